@@ -0,0 +1,106 @@
+// Package audio decodes PCM samples out of audio files. It exposes a small
+// Decoder interface implemented by one backend per container/codec (WAV,
+// FLAC, MP3, Ogg/Opus) so the spectrogram package never needs to know which
+// format it was handed.
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Decoder streams mono-interleaved PCM samples, normalized to the range
+// [-1, 1], out of an audio file. Read behaves like io.Reader: it returns the
+// number of samples read into p and any error encountered, including io.EOF
+// once the stream is exhausted.
+type Decoder interface {
+	Read(p []float64) (n int, err error)
+	SampleRate() int
+	Channels() int
+}
+
+// opener constructs a Decoder from an open file. r is seekable because some
+// formats (WAV) need to seek back to the start of the data chunk after
+// reading their header.
+type opener func(r io.ReadSeeker) (Decoder, error)
+
+// registry maps a lowercase file extension (including the leading dot) to
+// the opener responsible for that format.
+var registry = map[string]opener{}
+
+// magicSniffers is consulted, in order, when the file extension does not
+// resolve to a known format. Each sniffer inspects the leading bytes of the
+// file and returns the opener that recognizes them, or nil.
+var magicSniffers []func(header []byte) opener
+
+// Register adds a decoder for the given file extension (e.g. ".wav") to the
+// registry. It is called from the init functions of the format-specific
+// files in this package.
+func Register(ext string, open opener) {
+	registry[strings.ToLower(ext)] = open
+}
+
+// RegisterSniffer adds a magic-byte sniffer used as a fallback when a file's
+// extension is missing or unrecognized.
+func RegisterSniffer(sniff func(header []byte) opener) {
+	magicSniffers = append(magicSniffers, sniff)
+}
+
+// Open opens the audio file at path and returns a Decoder for it, selected
+// by file extension and, failing that, by sniffing the file's magic bytes.
+func Open(path string) (Decoder, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	open := registry[strings.ToLower(filepath.Ext(path))]
+	if open == nil {
+		header := make([]byte, 12)
+		n, _ := io.ReadFull(file, header)
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+		for _, sniff := range magicSniffers {
+			if o := sniff(header[:n]); o != nil {
+				open = o
+				break
+			}
+		}
+	}
+
+	if open == nil {
+		file.Close()
+		return nil, fmt.Errorf("audio: unrecognized file format: %s", path)
+	}
+
+	dec, err := open(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &fileDecoder{Decoder: dec, file: file}, nil
+}
+
+// fileDecoder pairs a format-specific Decoder with the *os.File backing it,
+// so callers can close the file through the returned Decoder via io.Closer
+// without every format implementation having to manage it itself.
+type fileDecoder struct {
+	Decoder
+	file *os.File
+}
+
+// Close closes the underlying file.
+func (fd *fileDecoder) Close() error {
+	return fd.file.Close()
+}
+
+// hasPrefix reports whether header starts with magic.
+func hasPrefix(header, magic []byte) bool {
+	return bytes.HasPrefix(header, magic)
+}
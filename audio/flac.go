@@ -0,0 +1,74 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"math"
+
+	"github.com/mewkiz/flac"
+)
+
+func init() {
+	Register(".flac", openFLAC)
+	RegisterSniffer(func(header []byte) opener {
+		if hasPrefix(header, []byte("fLaC")) {
+			return openFLAC
+		}
+		return nil
+	})
+}
+
+// flacDecoder decodes PCM samples from a FLAC stream via
+// github.com/mewkiz/flac, one frame at a time.
+type flacDecoder struct {
+	stream   *flac.Stream
+	channels int
+	divisor  float64
+	pending  []float64
+}
+
+func openFLAC(r io.ReadSeeker) (Decoder, error) {
+	stream, err := flac.New(r)
+	if err != nil {
+		return nil, err
+	}
+	if stream.Info.BitsPerSample == 0 || stream.Info.BitsPerSample > 32 {
+		return nil, errors.New("audio: unsupported FLAC bit depth")
+	}
+
+	return &flacDecoder{
+		stream:   stream,
+		channels: int(stream.Info.NChannels),
+		divisor:  math.Exp2(float64(stream.Info.BitsPerSample) - 1),
+	}, nil
+}
+
+func (d *flacDecoder) SampleRate() int { return int(d.stream.Info.SampleRate) }
+func (d *flacDecoder) Channels() int   { return d.channels }
+
+func (d *flacDecoder) Read(p []float64) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) == 0 {
+			frame, err := d.stream.ParseNext()
+			if err != nil {
+				if n == 0 {
+					return 0, err
+				}
+				return n, nil
+			}
+
+			nSamples := frame.Subframes[0].NSamples
+			d.pending = make([]float64, nSamples*d.channels)
+			for i := 0; i < nSamples; i++ {
+				for c := 0; c < d.channels; c++ {
+					d.pending[i*d.channels+c] = float64(frame.Subframes[c].Samples[i]) / d.divisor
+				}
+			}
+		}
+		copied := copy(p[n:], d.pending)
+		d.pending = d.pending[copied:]
+		n += copied
+	}
+	return n, nil
+}
@@ -0,0 +1,70 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+func init() {
+	Register(".mp3", openMP3)
+	RegisterSniffer(func(header []byte) opener {
+		if hasPrefix(header, []byte("ID3")) {
+			return openMP3
+		}
+		if len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0 {
+			return openMP3
+		}
+		return nil
+	})
+}
+
+// mp3Decoder decodes PCM samples from an MP3 stream via
+// github.com/hajimehoshi/go-mp3, which always yields 16-bit little-endian
+// stereo output regardless of the source encoding.
+type mp3Decoder struct {
+	dec     *mp3.Decoder
+	raw     []byte
+	pending []float64
+}
+
+func openMP3(r io.ReadSeeker) (Decoder, error) {
+	dec, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	return &mp3Decoder{dec: dec, raw: make([]byte, 4096)}, nil
+}
+
+func (d *mp3Decoder) SampleRate() int { return d.dec.SampleRate() }
+func (d *mp3Decoder) Channels() int   { return 2 }
+
+func (d *mp3Decoder) Read(p []float64) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) == 0 {
+			read, err := d.dec.Read(d.raw)
+			if read == 0 {
+				if n == 0 {
+					if err == nil {
+						err = io.EOF
+					}
+					return 0, err
+				}
+				return n, nil
+			}
+
+			samples := read / 2
+			d.pending = make([]float64, samples)
+			for i := 0; i < samples; i++ {
+				v := int16(binary.LittleEndian.Uint16(d.raw[i*2 : i*2+2]))
+				d.pending[i] = float64(v) / 32768.0
+			}
+		}
+		copied := copy(p[n:], d.pending)
+		d.pending = d.pending[copied:]
+		n += copied
+	}
+	return n, nil
+}
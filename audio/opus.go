@@ -0,0 +1,104 @@
+//go:build opus
+
+// Ogg/Opus decoding links against libopus and libopusfile via cgo, so it is
+// opt-in: build or test with `-tags opus` on a system that has those
+// libraries installed.
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	hropus "github.com/hraban/opus"
+)
+
+func init() {
+	Register(".opus", openOpus)
+	Register(".ogg", openOpus)
+	RegisterSniffer(func(header []byte) opener {
+		if hasPrefix(header, []byte("OggS")) {
+			return openOpus
+		}
+		return nil
+	})
+}
+
+// opusOutputRate is the rate libopus always decodes to, regardless of the
+// sample rate the stream was originally encoded at.
+const opusOutputRate = 48000
+
+// opusDecoder decodes PCM samples from an Ogg/Opus stream via
+// github.com/hraban/opus, which wraps libopusfile and handles the Ogg
+// demuxing internally.
+type opusDecoder struct {
+	stream   *hropus.Stream
+	channels int
+	raw      []int16
+	pending  []float64
+}
+
+func openOpus(r io.ReadSeeker) (Decoder, error) {
+	br := bufio.NewReader(r)
+	channels, err := peekOpusChannels(br)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := hropus.NewStream(br)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opusDecoder{
+		stream:   stream,
+		channels: channels,
+		raw:      make([]int16, 4096*channels),
+	}, nil
+}
+
+// peekOpusChannels looks ahead into the leading bytes of the Ogg stream to
+// find the OpusHead identification packet and read its channel count,
+// without consuming br (hropus.NewStream needs to see those bytes too).
+func peekOpusChannels(br *bufio.Reader) (int, error) {
+	const headSize = 4096
+	header, err := br.Peek(headSize)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	idx := bytes.Index(header, []byte("OpusHead"))
+	if idx < 0 || idx+9 >= len(header) {
+		return 0, errors.New("audio: could not find OpusHead in Ogg stream")
+	}
+	return int(header[idx+9]), nil
+}
+
+func (d *opusDecoder) SampleRate() int { return opusOutputRate }
+func (d *opusDecoder) Channels() int   { return d.channels }
+
+func (d *opusDecoder) Read(p []float64) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) == 0 {
+			read, err := d.stream.Read(d.raw)
+			if read == 0 {
+				if n == 0 {
+					return 0, err
+				}
+				return n, nil
+			}
+
+			total := read * d.channels
+			d.pending = make([]float64, total)
+			for i := 0; i < total; i++ {
+				d.pending[i] = float64(d.raw[i]) / 32768.0
+			}
+		}
+		copied := copy(p[n:], d.pending)
+		d.pending = d.pending[copied:]
+		n += copied
+	}
+	return n, nil
+}
@@ -0,0 +1,98 @@
+package audio
+
+import "math"
+
+// Downmix averages interleaved multi-channel samples down to a single mono
+// channel. If channels is 1, samples is returned unchanged.
+func Downmix(samples []float64, channels int) []float64 {
+	if channels <= 1 {
+		return samples
+	}
+
+	mono := make([]float64, len(samples)/channels)
+	for i := range mono {
+		var sum float64
+		for c := 0; c < channels; c++ {
+			sum += samples[i*channels+c]
+		}
+		mono[i] = sum / float64(channels)
+	}
+	return mono
+}
+
+// Resample converts mono samples recorded at srcRate to dstRate. The ratio
+// dstRate/srcRate is treated as the resampling ratio and applied by linear
+// interpolation between the two nearest source samples, which is sufficient
+// to bring arbitrary-rate input to the rate a Spectrograph was configured
+// for without pulling in a full polyphase resampler. When dstRate is lower
+// than srcRate, samples is first run through lowPassFIR so that energy
+// above the new Nyquist frequency is attenuated instead of aliasing back
+// into the visible band.
+func Resample(samples []float64, srcRate, dstRate int) []float64 {
+	if srcRate == dstRate || len(samples) == 0 {
+		return samples
+	}
+
+	if dstRate < srcRate {
+		nyquist := float64(dstRate) / 2
+		cutoff := nyquist / float64(srcRate)
+		samples = lowPassFIR(samples, cutoff, 63)
+	}
+
+	ratio := float64(dstRate) / float64(srcRate)
+	outLen := int(float64(len(samples)) * ratio)
+	out := make([]float64, outLen)
+
+	for i := range out {
+		srcPos := float64(i) / ratio
+		i0 := int(srcPos)
+		frac := srcPos - float64(i0)
+		if i0+1 >= len(samples) {
+			out[i] = samples[len(samples)-1]
+			continue
+		}
+		out[i] = samples[i0]*(1-frac) + samples[i0+1]*frac
+	}
+	return out
+}
+
+// lowPassFIR applies a windowed-sinc low-pass filter with the given cutoff
+// (as a fraction of srcRate, i.e. 0 to 0.5) and number of taps, used as an
+// anti-aliasing filter ahead of downsampling. Samples at the edges are
+// treated as zero beyond the ends of the slice.
+func lowPassFIR(samples []float64, cutoff float64, numTaps int) []float64 {
+	taps := make([]float64, numTaps)
+	center := float64(numTaps-1) / 2
+	var sum float64
+	for n := 0; n < numTaps; n++ {
+		m := float64(n) - center
+		var h float64
+		if m == 0 {
+			h = 2 * cutoff
+		} else {
+			h = math.Sin(2*math.Pi*cutoff*m) / (math.Pi * m)
+		}
+		// Blackman window, to keep stopband ripple low.
+		h *= 0.42 - 0.5*math.Cos(2*math.Pi*float64(n)/float64(numTaps-1)) + 0.08*math.Cos(4*math.Pi*float64(n)/float64(numTaps-1))
+		taps[n] = h
+		sum += h
+	}
+	for n := range taps {
+		taps[n] /= sum
+	}
+
+	out := make([]float64, len(samples))
+	half := numTaps / 2
+	for i := range out {
+		var acc float64
+		for n := 0; n < numTaps; n++ {
+			j := i + n - half
+			if j < 0 || j >= len(samples) {
+				continue
+			}
+			acc += samples[j] * taps[n]
+		}
+		out[i] = acc
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	goaudio "github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+func init() {
+	Register(".wav", openWAV)
+	RegisterSniffer(func(header []byte) opener {
+		if hasPrefix(header, []byte("RIFF")) {
+			return openWAV
+		}
+		return nil
+	})
+}
+
+// wavDecoder decodes PCM samples from a WAV file via github.com/go-audio/wav.
+type wavDecoder struct {
+	dec      *wav.Decoder
+	divisor  float64
+	channels int
+	buf      *goaudio.IntBuffer
+	pending  []float64
+}
+
+func openWAV(r io.ReadSeeker) (Decoder, error) {
+	dec := wav.NewDecoder(r)
+	dec.ReadInfo()
+	if !dec.IsValidFile() {
+		return nil, errors.New("audio: input is not a valid WAV file")
+	}
+
+	var divisor float64
+	switch dec.BitDepth {
+	case 16:
+		divisor = 32768.0
+	case 24:
+		divisor = 8388608.0
+	case 32:
+		divisor = 2147483648.0
+	default:
+		return nil, fmt.Errorf("audio: unsupported WAV bit depth: %d", dec.BitDepth)
+	}
+
+	channels := int(dec.NumChans)
+	return &wavDecoder{
+		dec:      dec,
+		divisor:  divisor,
+		channels: channels,
+		buf: &goaudio.IntBuffer{
+			Data:   make([]int, 4096*channels),
+			Format: &goaudio.Format{SampleRate: int(dec.SampleRate), NumChannels: channels},
+		},
+	}, nil
+}
+
+func (d *wavDecoder) SampleRate() int { return int(d.dec.SampleRate) }
+func (d *wavDecoder) Channels() int   { return d.channels }
+
+func (d *wavDecoder) Read(p []float64) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) == 0 {
+			read, err := d.dec.PCMBuffer(d.buf)
+			if err != nil {
+				return n, err
+			}
+			if read == 0 {
+				if n == 0 {
+					return 0, io.EOF
+				}
+				return n, nil
+			}
+			d.pending = make([]float64, read)
+			for i, s := range d.buf.Data[:read] {
+				d.pending[i] = float64(s) / d.divisor
+			}
+		}
+		copied := copy(p[n:], d.pending)
+		d.pending = d.pending[copied:]
+		n += copied
+	}
+	return n, nil
+}
@@ -0,0 +1,89 @@
+package spectrogram
+
+import "math"
+
+// FrequencyScale selects how FFT bins are mapped onto the frequency axis
+// (the rows) of a Spectrogram.
+type FrequencyScale int
+
+const (
+	// Linear maps each output row directly to an FFT bin, spacing
+	// frequencies evenly. This is the scale the tool has always used.
+	Linear FrequencyScale = iota
+	// Log maps output rows to frequencies spaced logarithmically between
+	// the Spectrograph's frequency range, interpolating between the two
+	// nearest FFT bins.
+	Log
+	// Mel maps output rows to a mel filter bank, summing the power of the
+	// FFT bins that fall under each triangular filter.
+	Mel
+)
+
+// hzToMel converts a frequency in Hz to the mel scale.
+func hzToMel(f float64) float64 {
+	return 2595 * math.Log10(1+f/700)
+}
+
+// melToHz converts a mel-scale value back to Hz.
+func melToHz(m float64) float64 {
+	return 700 * (math.Pow(10, m/2595) - 1)
+}
+
+// melFilterBank builds nMels overlapping triangular filters spanning fMin to
+// fMax Hz. Each filter is returned as a slice of per-FFT-bin weights, sized
+// to numBins, so that a band's power is sum(weights[bin] * mag[bin]^2).
+// binHz is the frequency spacing between adjacent FFT bins.
+func melFilterBank(nMels int, fMin, fMax float64, numBins int, binHz float64) [][]float64 {
+	melMin := hzToMel(fMin)
+	melMax := hzToMel(fMax)
+
+	// nMels+2 points uniformly spaced in mel space, converted back to Hz and
+	// then to the nearest FFT bin, give the edges of nMels overlapping
+	// triangles.
+	points := make([]int, nMels+2)
+	for i := range points {
+		m := melMin + (melMax-melMin)*float64(i)/float64(nMels+1)
+		bin := int(math.Round(melToHz(m) / binHz))
+		if bin < 0 {
+			bin = 0
+		}
+		if bin > numBins-1 {
+			bin = numBins - 1
+		}
+		points[i] = bin
+	}
+
+	filters := make([][]float64, nMels)
+	for i := 0; i < nMels; i++ {
+		left, center, right := points[i], points[i+1], points[i+2]
+		weights := make([]float64, numBins)
+		for bin := left; bin < center; bin++ {
+			if center != left {
+				weights[bin] = float64(bin-left) / float64(center-left)
+			}
+		}
+		for bin := center; bin < right; bin++ {
+			if right != center {
+				weights[bin] = float64(right-bin) / float64(right-center)
+			}
+		}
+		filters[i] = weights
+	}
+	return filters
+}
+
+// interpolateBin linearly interpolates the magnitude at frequency freq
+// between the two nearest entries of mags, which are spaced binHz apart
+// starting at 0 Hz.
+func interpolateBin(mags []float64, freq, binHz float64) float64 {
+	pos := freq / binHz
+	i0 := int(pos)
+	if i0 < 0 {
+		i0 = 0
+	}
+	if i0 >= len(mags)-1 {
+		return mags[len(mags)-1]
+	}
+	frac := pos - float64(i0)
+	return mags[i0]*(1-frac) + mags[i0+1]*frac
+}
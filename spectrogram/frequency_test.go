@@ -0,0 +1,47 @@
+package spectrogram
+
+import "testing"
+
+func TestMelFilterBankShape(t *testing.T) {
+	const numBins = 1024
+	const nMels = 40
+	binHz := 24000.0 / numBins
+
+	filters := melFilterBank(nMels, 20, 20000, numBins, binHz)
+	if len(filters) != nMels {
+		t.Fatalf("got %d filters, want %d", len(filters), nMels)
+	}
+
+	for i, weights := range filters {
+		if len(weights) != numBins {
+			t.Fatalf("filter %d has %d weights, want %d", i, len(weights), numBins)
+		}
+		for bin, w := range weights {
+			if w < 0 || w > 1 {
+				t.Errorf("filter %d bin %d weight = %v, want [0, 1]", i, bin, w)
+			}
+		}
+	}
+}
+
+func TestInterpolateBin(t *testing.T) {
+	mags := []float64{0, 10, 20, 30}
+	binHz := 1.0
+
+	tests := []struct {
+		freq float64
+		want float64
+	}{
+		{0, 0},
+		{1, 10},
+		{1.5, 15},
+		{3, 30},
+		{10, 30}, // past the end clamps to the last bin
+	}
+
+	for _, tt := range tests {
+		if got := interpolateBin(mags, tt.freq, binHz); got != tt.want {
+			t.Errorf("interpolateBin(mags, %v, %v) = %v, want %v", tt.freq, binHz, got, tt.want)
+		}
+	}
+}
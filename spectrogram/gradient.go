@@ -0,0 +1,68 @@
+package spectrogram
+
+import "image/color"
+
+// GradientStop is a single color stop used to build a Gradient with
+// NewGradient, as a lighter-weight alternative to specifying a full list of
+// ColorThresholds directly.
+type GradientStop struct {
+	Pos   float64
+	Color color.RGBA
+}
+
+// Gradient maps dBFS values to colors for rendering. It is a sorted list of
+// ColorThresholds; colorFor returns the color of the first threshold whose
+// Value is greater than or equal to the sample.
+type Gradient []ColorThreshold
+
+// NewGradient builds a Gradient from a small number of stops, expanding it
+// through the same fine-graining used by the built-in presets so that
+// transitions between stops stay smooth.
+func NewGradient(stops []GradientStop) Gradient {
+	base := make([]ColorThreshold, len(stops))
+	for i, s := range stops {
+		base[i] = ColorThreshold{Value: s.Pos, Color: s.Color}
+	}
+	return Gradient(generateFineGrainedPalette(base))
+}
+
+// colorFor returns the color for a given dBFS value, defaulting to white if
+// dBFS exceeds every threshold in the gradient.
+func (g Gradient) colorFor(dBFS float64) color.RGBA {
+	for _, threshold := range g {
+		if dBFS <= threshold.Value {
+			return threshold.Color
+		}
+	}
+	return color.RGBA{255, 255, 255, 255}
+}
+
+// GradientAudacity is the blue-to-white palette this tool has always used,
+// modeled on Audacity's default spectrogram colors. It is the default
+// gradient used by ToPNG when RenderOptions.Gradient is left nil.
+var GradientAudacity = Gradient(generateFineGrainedPalette(baseColorPalette))
+
+// GradientGrayscale ramps from black to white.
+var GradientGrayscale = NewGradient([]GradientStop{
+	{Pos: -120, Color: color.RGBA{0, 0, 0, 255}},
+	{Pos: 0, Color: color.RGBA{255, 255, 255, 255}},
+})
+
+// GradientViridis approximates the perceptually-uniform viridis colormap
+// popularized by matplotlib.
+var GradientViridis = NewGradient([]GradientStop{
+	{Pos: -120, Color: color.RGBA{68, 1, 84, 255}},
+	{Pos: -90, Color: color.RGBA{59, 82, 139, 255}},
+	{Pos: -60, Color: color.RGBA{33, 145, 140, 255}},
+	{Pos: -30, Color: color.RGBA{94, 201, 98, 255}},
+	{Pos: 0, Color: color.RGBA{253, 231, 37, 255}},
+})
+
+// GradientInferno approximates matplotlib's inferno colormap.
+var GradientInferno = NewGradient([]GradientStop{
+	{Pos: -120, Color: color.RGBA{0, 0, 4, 255}},
+	{Pos: -90, Color: color.RGBA{87, 16, 110, 255}},
+	{Pos: -60, Color: color.RGBA{188, 55, 84, 255}},
+	{Pos: -30, Color: color.RGBA{249, 142, 9, 255}},
+	{Pos: 0, Color: color.RGBA{252, 255, 164, 255}},
+})
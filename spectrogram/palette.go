@@ -0,0 +1,103 @@
+package spectrogram
+
+import "image/color"
+
+// ColorThreshold associates a dBFS value with the color that should be used
+// to represent it in a rendered spectrogram.
+type ColorThreshold struct {
+	Value float64
+	Color color.RGBA
+}
+
+var baseColorPalette = []ColorThreshold{
+	{-120, color.RGBA{0, 0, 0, 255}},       // black
+	{-117.5, color.RGBA{0, 0, 17, 255}},    // very very very dark blue
+	{-115, color.RGBA{0, 0, 34, 255}},      // very very dark blue
+	{-112.5, color.RGBA{0, 0, 51, 255}},    // deeper dark blue
+	{-110, color.RGBA{0, 0, 69, 255}},      // very dark blue
+	{-107.5, color.RGBA{0, 0, 86, 255}},    // deeper blue
+	{-105, color.RGBA{0, 0, 104, 255}},     // darker blue
+	{-102.5, color.RGBA{0, 0, 121, 255}},   // more dark blue
+	{-100, color.RGBA{0, 0, 139, 255}},     // dark blue
+	{-97.5, color.RGBA{0, 0, 155, 255}},    // intermediate dark blue
+	{-95, color.RGBA{0, 0, 172, 255}},      // medium dark blue
+	{-92.5, color.RGBA{0, 0, 188, 255}},    // brighter dark blue
+	{-90, color.RGBA{0, 0, 205, 255}},      // medium blue
+	{-87.5, color.RGBA{0, 0, 218, 255}},    // medium bright blue
+	{-85, color.RGBA{0, 0, 230, 255}},      // brighter blue
+	{-82.5, color.RGBA{0, 0, 242, 255}},    // much brighter blue
+	{-80, color.RGBA{0, 0, 255, 255}},      // blue
+	{-77.5, color.RGBA{19, 0, 223, 255}},   // deep blue-indigo
+	{-75, color.RGBA{38, 0, 192, 255}},     // indigo-ish
+	{-72.5, color.RGBA{57, 0, 161, 255}},   // deeper indigo
+	{-70, color.RGBA{75, 0, 130, 255}},     // indigo
+	{-67.5, color.RGBA{94, 0, 150, 255}},   // indigo-violet mix
+	{-65, color.RGBA{112, 0, 171, 255}},    // dark violet-ish
+	{-62.5, color.RGBA{130, 0, 191, 255}},  // darker violet
+	{-60, color.RGBA{148, 0, 211, 255}},    // dark violet
+	{-57.5, color.RGBA{146, 0, 193, 255}},  // violet-ish
+	{-55, color.RGBA{144, 0, 175, 255}},    // medium violet
+	{-52.5, color.RGBA{142, 0, 157, 255}},  // less violet
+	{-50, color.RGBA{139, 0, 139, 255}},    // dark magenta
+	{-47.5, color.RGBA{168, 0, 104, 255}},  // magenta-red mix
+	{-45, color.RGBA{197, 0, 69, 255}},     // magenta-red-ish
+	{-42.5, color.RGBA{226, 0, 34, 255}},   // deep red
+	{-40, color.RGBA{255, 0, 0, 255}},      // red
+	{-37.5, color.RGBA{255, 18, 0, 255}},   // deep red-orange
+	{-35, color.RGBA{255, 35, 0, 255}},     // red-orange mix
+	{-32.5, color.RGBA{255, 52, 0, 255}},   // more orange than red
+	{-30, color.RGBA{255, 69, 0, 255}},     // red-orange
+	{-27.5, color.RGBA{255, 93, 0, 255}},   // orange-ish
+	{-25, color.RGBA{255, 117, 0, 255}},    // deeper orange
+	{-22.5, color.RGBA{255, 141, 0, 255}},  // less deep orange
+	{-20, color.RGBA{255, 165, 0, 255}},    // orange
+	{-17.5, color.RGBA{255, 188, 0, 255}},  // light orange
+	{-15, color.RGBA{255, 210, 0, 255}},    // brighter light orange
+	{-12.5, color.RGBA{255, 233, 0, 255}},  // very light orange
+	{-10, color.RGBA{255, 255, 0, 255}},    // yellow
+	{-7.5, color.RGBA{255, 255, 64, 255}},  // light yellow
+	{-5, color.RGBA{255, 255, 128, 255}},   // very light yellow
+	{-2.5, color.RGBA{255, 255, 192, 255}}, // pale yellow
+	{0, color.RGBA{255, 255, 255, 255}},    // white
+}
+
+// interpolateColor interpolates between two colors (c1 and c2) based on a given fraction.
+// It linearly interpolates each RGB channel of the two colors. The alpha channel is set to 255.
+// For example, a fraction of 0.5 will give a color halfway between c1 and c2.
+func interpolateColor(c1, c2 color.RGBA, fraction float64) color.RGBA {
+	return color.RGBA{
+		// Interpolate the red channel.
+		uint8(float64(c1.R) + fraction*(float64(c2.R)-float64(c1.R))),
+		// Interpolate the green channel.
+		uint8(float64(c1.G) + fraction*(float64(c2.G)-float64(c1.G))),
+		// Interpolate the blue channel.
+		uint8(float64(c1.B) + fraction*(float64(c2.B)-float64(c1.B))),
+		// Set alpha channel to maximum (opaque).
+		255,
+	}
+}
+
+// generateFineGrainedPalette takes a base palette of ColorThresholds and interpolates
+// to create a more fine-grained palette. This provides smoother color transitions.
+func generateFineGrainedPalette(base []ColorThreshold) []ColorThreshold {
+	var fineGrainedPalette []ColorThreshold
+
+	// Iterate through the base palette. For each pair of consecutive colors,
+	// add the first color, then an interpolated color halfway between the pair.
+	for i := 0; i < len(base)-1; i++ {
+		// Append the current color from the base palette.
+		fineGrainedPalette = append(fineGrainedPalette, base[i])
+
+		// Calculate the average value between the current and next threshold.
+		interpolatedValue := (base[i].Value + base[i+1].Value) / 2
+		// Interpolate a color halfway between the current and next color.
+		interpolatedColor := interpolateColor(base[i].Color, base[i+1].Color, 0.5)
+		// Append the interpolated color and value.
+		fineGrainedPalette = append(fineGrainedPalette, ColorThreshold{interpolatedValue, interpolatedColor})
+	}
+	// Append the last color from the base palette to the fine-grained palette.
+	fineGrainedPalette = append(fineGrainedPalette, base[len(base)-1])
+
+	// Return the newly generated fine-grained palette.
+	return fineGrainedPalette
+}
@@ -0,0 +1,263 @@
+package spectrogram
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/fogleman/gg"
+)
+
+// baseFontHeight is the line height, in pixels, of gg's default font face
+// (basicfont.Face7x13). RenderOptions.FontSize is applied by scaling text
+// drawn with that face relative to this height, so axis labels stay legible
+// without bundling a TTF.
+const baseFontHeight = 13
+
+// defaultFontSize and defaultMargin are used when RenderOptions leaves
+// FontSize or Margin unset.
+const (
+	defaultFontSize = 12.0
+	defaultMargin   = 8.0
+)
+
+// numAxisTicks is the number of gridlines drawn along each axis that is
+// enabled.
+const numAxisTicks = 5
+
+// RenderOptions controls how a Spectrogram is rasterized to an image. A
+// zero-value RenderOptions renders at the spectrogram's native width and
+// height using GradientAudacity, with no axes, gridlines, or colorbar.
+type RenderOptions struct {
+	// Width and Height override the output image size. Zero means use the
+	// spectrogram's native Width/Height.
+	Width  int
+	Height int
+	// Gradient maps dBFS values to pixel colors. Nil means GradientAudacity.
+	Gradient Gradient
+
+	// ShowTimeAxis draws a row of second labels and light vertical
+	// gridlines below the spectrogram, derived from hopSize/sampleRate.
+	ShowTimeAxis bool
+	// ShowFrequencyAxis draws a column of Hz/kHz labels and light
+	// horizontal gridlines to the left of the spectrogram, derived from
+	// sampleRate/2 and the FrequencyScale the spectrogram was computed with.
+	ShowFrequencyAxis bool
+	// ShowColorbar draws a vertical dB scale to the right of the
+	// spectrogram, mapping Gradient's thresholds to their dB values.
+	ShowColorbar bool
+	// TitleText, if non-empty, is drawn centered above the spectrogram.
+	TitleText string
+
+	// FontSize is the point size used for the title, axis labels, and the
+	// colorbar scale. Zero means 12.
+	FontSize float64
+	// Margin is the padding, in pixels, around axis labels and the
+	// colorbar. Zero means 8.
+	Margin float64
+}
+
+// ToPNG renders the spectrogram as a PNG image and writes it to w.
+func (sg *Spectrogram) ToPNG(w io.Writer, opts RenderOptions) error {
+	dc := sg.plot(opts)
+	return dc.EncodePNG(w)
+}
+
+// plot converts the spectrogram's raw magnitude matrix to dBFS and draws it
+// into a gg.Context, mapping each value to a color via opts.Gradient,
+// surrounded by whichever of the axes, gridlines, colorbar, and title opts
+// requests.
+func (sg *Spectrogram) plot(opts RenderOptions) *gg.Context {
+	plotWidth := opts.Width
+	if plotWidth <= 0 {
+		plotWidth = sg.Width()
+	}
+	plotHeight := opts.Height
+	if plotHeight <= 0 {
+		plotHeight = sg.Height()
+	}
+	gradient := opts.Gradient
+	if gradient == nil {
+		gradient = GradientAudacity
+	}
+	fontSize := opts.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultFontSize
+	}
+	margin := opts.Margin
+	if margin <= 0 {
+		margin = defaultMargin
+	}
+
+	var left, right, top, bottom float64
+	if opts.ShowFrequencyAxis {
+		left = fontSize*4 + margin*2
+	}
+	if opts.ShowTimeAxis {
+		bottom = fontSize + margin*2
+	}
+	if opts.TitleText != "" {
+		top = fontSize + margin*2
+	}
+	colorbarWidth := fontSize * 1.5
+	if opts.ShowColorbar {
+		right = colorbarWidth + fontSize*3 + margin*2
+	}
+
+	width := plotWidth + int(math.Ceil(left+right))
+	height := plotHeight + int(math.Ceil(top+bottom))
+
+	dc := gg.NewContext(width, height)
+	dc.SetColor(color.RGBA{0, 0, 0, 255})
+	dc.Clear()
+
+	for x := 0; x < plotWidth && x < sg.Width(); x++ {
+		row := sg.mags[x]
+		for y := 0; y < plotHeight && y < len(row); y++ {
+			dBFS := magnitudeToDBFS(row[y], sg.windowEnergy)
+			dc.SetColor(gradient.colorFor(dBFS))
+			dc.SetPixel(int(left)+x, int(top)+plotHeight-y-1)
+		}
+	}
+
+	if opts.ShowFrequencyAxis {
+		sg.drawFrequencyAxis(dc, left, top, float64(plotWidth), float64(plotHeight), fontSize)
+	}
+	if opts.ShowTimeAxis {
+		sg.drawTimeAxis(dc, left, top, float64(plotWidth), float64(plotHeight), fontSize)
+	}
+	if opts.ShowColorbar {
+		drawColorbar(dc, left+float64(plotWidth)+margin, top, colorbarWidth, float64(plotHeight), gradient, fontSize)
+	}
+	if opts.TitleText != "" {
+		drawLabel(dc, opts.TitleText, left+float64(plotWidth)/2, top/2, 0.5, 0.5, fontSize)
+	}
+
+	return dc
+}
+
+// drawFrequencyAxis draws numAxisTicks+1 evenly spaced Hz/kHz labels and
+// light horizontal gridlines across the plotted area, which occupies
+// [plotX, plotX+plotWidth) x [plotY, plotY+plotHeight).
+func (sg *Spectrogram) drawFrequencyAxis(dc *gg.Context, plotX, plotY, plotWidth, plotHeight, fontSize float64) {
+	for i := 0; i <= numAxisTicks; i++ {
+		frac := float64(i) / float64(numAxisTicks)
+		y := plotY + plotHeight*(1-frac)
+		row := int(frac * float64(sg.Height()-1))
+
+		dc.SetColor(color.RGBA{60, 60, 60, 255})
+		dc.SetLineWidth(1)
+		dc.DrawLine(plotX, y, plotX+plotWidth, y)
+		dc.Stroke()
+
+		drawLabel(dc, formatHz(sg.frequencyAtRow(row)), plotX-4, y, 1, 0.5, fontSize)
+	}
+}
+
+// drawTimeAxis draws numAxisTicks+1 evenly spaced second labels and light
+// vertical gridlines below the plotted area.
+func (sg *Spectrogram) drawTimeAxis(dc *gg.Context, plotX, plotY, plotWidth, plotHeight, fontSize float64) {
+	secondsPerFrame := float64(sg.hopSize) / float64(sg.sampleRate)
+
+	for i := 0; i <= numAxisTicks; i++ {
+		frac := float64(i) / float64(numAxisTicks)
+		x := plotX + plotWidth*frac
+		frame := frac * float64(sg.Width()-1)
+
+		dc.SetColor(color.RGBA{60, 60, 60, 255})
+		dc.SetLineWidth(1)
+		dc.DrawLine(x, plotY, x, plotY+plotHeight)
+		dc.Stroke()
+
+		drawLabel(dc, fmt.Sprintf("%.1fs", frame*secondsPerFrame), x, plotY+plotHeight+4, 0.5, 0, fontSize)
+	}
+}
+
+// drawColorbar draws a vertical bar at (x, y) of the given size, filled with
+// gradient from its lowest dBFS threshold at the bottom to its highest at
+// the top, labeled at each of numAxisTicks+1 evenly spaced points.
+func drawColorbar(dc *gg.Context, x, y, w, h float64, gradient Gradient, fontSize float64) {
+	minDB := gradient[0].Value
+	maxDB := gradient[len(gradient)-1].Value
+
+	for row := 0; row < int(h); row++ {
+		frac := float64(row) / h
+		db := maxDB - frac*(maxDB-minDB)
+		dc.SetColor(gradient.colorFor(db))
+		dc.DrawLine(x, y+float64(row), x+w, y+float64(row))
+		dc.Stroke()
+	}
+
+	for i := 0; i <= numAxisTicks; i++ {
+		frac := float64(i) / float64(numAxisTicks)
+		labelY := y + h*frac
+		db := maxDB - frac*(maxDB-minDB)
+		drawLabel(dc, fmt.Sprintf("%.0f", db), x+w+4, labelY, 0, 0.5, fontSize)
+	}
+}
+
+// drawLabel draws s anchored at (x, y) with anchor fractions (ax, ay), as
+// documented by gg.Context.DrawStringAnchored, scaling gg's default font
+// face to fontSize points.
+func drawLabel(dc *gg.Context, s string, x, y, ax, ay, fontSize float64) {
+	scale := fontSize / baseFontHeight
+	dc.Push()
+	dc.Translate(x, y)
+	dc.Scale(scale, scale)
+	dc.SetColor(color.RGBA{200, 200, 200, 255})
+	dc.DrawStringAnchored(s, 0, 0, ax, ay)
+	dc.Pop()
+}
+
+// formatHz renders a frequency for axis labels, switching to kHz above 1000
+// Hz to keep labels short.
+func formatHz(hz float64) string {
+	if hz >= 1000 {
+		return fmt.Sprintf("%.1fk", hz/1000)
+	}
+	return fmt.Sprintf("%.0f", hz)
+}
+
+// frequencyAtRow returns the frequency, in Hz, that row y of the spectrogram
+// represents, accounting for the FrequencyScale it was computed with.
+func (sg *Spectrogram) frequencyAtRow(y int) float64 {
+	bins := sg.Height()
+	if bins <= 1 {
+		return 0
+	}
+
+	switch sg.freqScale {
+	case Log:
+		return sg.fMin * math.Pow(sg.fMax/sg.fMin, float64(y)/float64(bins))
+	case Mel:
+		melMin, melMax := hzToMel(sg.fMin), hzToMel(sg.fMax)
+		mel := melMin + (melMax-melMin)*float64(y+1)/float64(bins+1)
+		return melToHz(mel)
+	default: // Linear
+		nyquist := float64(sg.sampleRate) / 2
+		return float64(y) / float64(bins) * nyquist
+	}
+}
+
+// ToCSV writes the raw magnitude matrix to w as comma-separated rows, one
+// row per time frame and one column per frequency bin, with no dBFS
+// conversion applied.
+func (sg *Spectrogram) ToCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	for _, row := range sg.mags {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = strconv.FormatFloat(v, 'f', 4, 64)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return writer.Error()
+}
@@ -0,0 +1,41 @@
+package spectrogram
+
+// Spectrogram holds the raw magnitude matrix produced by Spectrograph.Compute.
+// Each element mags[x][y] is the linear FFT magnitude of frequency row y (an
+// FFT bin, mel band, or log-spaced frequency, depending on FrequencyScale) in
+// time frame x. Magnitudes are only converted to dBFS at render/export time
+// (see ToPNG and ToCSV), so embedders that want raw magnitude or power for
+// their own feature extraction are never forced through that conversion.
+type Spectrogram struct {
+	mags         [][]float64
+	windowEnergy float64
+	bins         int
+	hopSize      int
+	sampleRate   int
+	freqScale    FrequencyScale
+	fMin, fMax   float64
+}
+
+// Width returns the number of time frames in the spectrogram.
+func (sg *Spectrogram) Width() int {
+	return len(sg.mags)
+}
+
+// Height returns the number of frequency rows in the spectrogram.
+func (sg *Spectrogram) Height() int {
+	return sg.bins
+}
+
+// FrequencyScale returns the frequency scale the spectrogram was computed
+// with.
+func (sg *Spectrogram) FrequencyScale() FrequencyScale {
+	return sg.freqScale
+}
+
+// Magnitudes returns the raw FFT magnitude matrix, indexed [x][y] as time
+// frame by frequency row, with no dBFS conversion applied. This is the value
+// a caller embedding the package for feature extraction (e.g. a bird-ID
+// pipeline) wants instead of ToPNG/ToCSV's rendered dBFS.
+func (sg *Spectrogram) Magnitudes() [][]float64 {
+	return sg.mags
+}
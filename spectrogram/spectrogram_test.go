@@ -0,0 +1,56 @@
+package spectrogram
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeDimensions(t *testing.T) {
+	pcm := make([]float64, 4096)
+	sg := NewSpectrograph().SetFFTSize(256).SetStepSize(128).Compute(pcm)
+
+	wantWidth := (len(pcm)-256)/128 + 1
+	if got := sg.Width(); got != wantWidth {
+		t.Errorf("Width() = %d, want %d", got, wantWidth)
+	}
+	if got := sg.Height(); got != 128 {
+		t.Errorf("Height() = %d, want %d", got, 128)
+	}
+}
+
+func TestComputeMagnitudesAreRaw(t *testing.T) {
+	const fftSize = 256
+	const freqBin = 10
+	sampleRate := 8000
+	freq := float64(freqBin) * float64(sampleRate) / fftSize
+
+	pcm := make([]float64, fftSize*4)
+	for i := range pcm {
+		pcm[i] = math.Sin(2 * math.Pi * freq * float64(i) / float64(sampleRate))
+	}
+
+	sg := NewSpectrograph().SetFFTSize(fftSize).SetStepSize(fftSize).SetSampleRate(sampleRate).Compute(pcm)
+	mags := sg.Magnitudes()
+
+	if len(mags) == 0 {
+		t.Fatal("Magnitudes() returned no frames")
+	}
+
+	row := mags[0]
+	peak := 0
+	for y, v := range row {
+		if v > row[peak] {
+			peak = y
+		}
+	}
+	if peak != freqBin {
+		t.Errorf("peak bin = %d, want %d", peak, freqBin)
+	}
+
+	// Magnitudes should be linear amplitude, not dBFS: a pure tone at full
+	// scale should have a positive peak, never the negative values dBFS
+	// conversion would produce.
+	if row[peak] <= 0 {
+		t.Errorf("peak magnitude = %v, want a positive linear magnitude", row[peak])
+	}
+}
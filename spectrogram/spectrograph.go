@@ -0,0 +1,199 @@
+// Package spectrogram computes and renders STFT spectrograms from PCM audio
+// data. It is split out of the go-spectrogram command so the FFT/plotting
+// core can be embedded in other programs without depending on the CLI.
+package spectrogram
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+// DefaultSampleRate is the sample rate assumed by NewSpectrograph when none
+// is set explicitly.
+const DefaultSampleRate = 48000
+
+// WindowFunc computes a window function of length n, matching the signature
+// of the functions in github.com/mjibson/go-dsp/window.
+type WindowFunc func(n int) []float64
+
+// Spectrograph is a builder that configures and runs an STFT over PCM audio
+// data. Each Set method returns the receiver so calls can be chained, e.g.:
+//
+//	sg := NewSpectrograph().SetFFTSize(2048).SetStepSize(880).Compute(pcm)
+type Spectrograph struct {
+	fftSize    int
+	stepSize   int
+	sampleRate int
+	window     WindowFunc
+	freqScale  FrequencyScale
+	nMels      int
+	fMin       float64
+	fMax       float64
+}
+
+// NewSpectrograph returns a Spectrograph configured with the defaults this
+// tool has always used: a 2048-sample FFT, an 880-sample hop, 48 kHz, a Hann
+// window, and a Linear frequency scale.
+func NewSpectrograph() *Spectrograph {
+	return &Spectrograph{
+		fftSize:    2048,
+		stepSize:   880,
+		sampleRate: DefaultSampleRate,
+		window:     WindowHann,
+		freqScale:  Linear,
+		nMels:      128,
+	}
+}
+
+// SetFFTSize sets the number of samples per FFT frame.
+func (s *Spectrograph) SetFFTSize(n int) *Spectrograph {
+	s.fftSize = n
+	return s
+}
+
+// SetStepSize sets the number of samples advanced between consecutive
+// frames (the hop size).
+func (s *Spectrograph) SetStepSize(n int) *Spectrograph {
+	s.stepSize = n
+	return s
+}
+
+// SetSampleRate records the sample rate of the PCM data that will be passed
+// to Compute. It does not resample; it is carried through to the resulting
+// Spectrogram for rendering.
+func (s *Spectrograph) SetSampleRate(sr int) *Spectrograph {
+	s.sampleRate = sr
+	return s
+}
+
+// SetWindow sets the window function applied to each frame before the FFT.
+func (s *Spectrograph) SetWindow(w WindowFunc) *Spectrograph {
+	s.window = w
+	return s
+}
+
+// SetFrequencyScale selects how FFT bins are mapped onto the frequency axis
+// of the resulting Spectrogram. The default is Linear.
+func (s *Spectrograph) SetFrequencyScale(scale FrequencyScale) *Spectrograph {
+	s.freqScale = scale
+	return s
+}
+
+// SetMelBands sets the number of mel filter bank bands produced when the
+// frequency scale is Mel. Defaults to 128.
+func (s *Spectrograph) SetMelBands(n int) *Spectrograph {
+	s.nMels = n
+	return s
+}
+
+// SetFrequencyRange sets the minimum and maximum frequency, in Hz, spanned
+// by the Log and Mel frequency scales. It has no effect on Linear. Defaults
+// to 20 Hz .. sampleRate/2.
+func (s *Spectrograph) SetFrequencyRange(fMin, fMax float64) *Spectrograph {
+	s.fMin = fMin
+	s.fMax = fMax
+	return s
+}
+
+// frequencyRange returns the configured frequency range, filling in the
+// defaults (20 Hz .. Nyquist) where the caller left it unset.
+func (s *Spectrograph) frequencyRange() (fMin, fMax float64) {
+	fMin, fMax = s.fMin, s.fMax
+	if fMin <= 0 {
+		fMin = 20
+	}
+	if fMax <= 0 {
+		fMax = float64(s.sampleRate) / 2
+	}
+	return fMin, fMax
+}
+
+// Compute runs the STFT over pcm and returns the resulting Spectrogram, with
+// each frame's spectrum mapped onto the configured FrequencyScale.
+func (s *Spectrograph) Compute(pcm []float64) *Spectrogram {
+	fftSize := s.fftSize
+	hopSize := s.stepSize
+	windowFunc := s.window(fftSize)
+
+	// Calculate the total energy in the window function, used to normalize
+	// the FFT magnitudes into dBFS.
+	windowEnergy := 0.0
+	for _, w := range windowFunc {
+		windowEnergy += w * w
+	}
+
+	numBins := fftSize / 2
+	binHz := float64(s.sampleRate) / float64(fftSize)
+	fMin, fMax := s.frequencyRange()
+
+	var filters [][]float64
+	outBins := numBins
+	if s.freqScale == Mel {
+		filters = melFilterBank(s.nMels, fMin, fMax, numBins, binHz)
+		outBins = s.nMels
+	}
+
+	width := 0
+	if len(pcm) >= fftSize {
+		width = (len(pcm)-fftSize)/hopSize + 1
+	}
+
+	frames := make([][]float64, width)
+	for x := 0; x < width; x++ {
+		start := x * hopSize
+
+		// Apply the window function to the PCM data to smooth its edges.
+		src := make([]float64, fftSize)
+		for i := 0; i < fftSize; i++ {
+			src[i] = pcm[start+i] * windowFunc[i]
+		}
+
+		// Compute the FFT of the windowed data, yielding frequency coefficients.
+		spectrum := fft.FFTReal(src)
+		mags := make([]float64, numBins)
+		for y := 0; y < numBins; y++ {
+			mags[y] = cmplx.Abs(spectrum[y])
+		}
+
+		row := make([]float64, outBins)
+		switch s.freqScale {
+		case Mel:
+			for band, weights := range filters {
+				power := 0.0
+				for bin, w := range weights {
+					if w != 0 {
+						power += w * mags[bin] * mags[bin]
+					}
+				}
+				row[band] = math.Sqrt(power)
+			}
+		case Log:
+			for y := 0; y < outBins; y++ {
+				freq := fMin * math.Pow(fMax/fMin, float64(y)/float64(outBins))
+				row[y] = interpolateBin(mags, freq, binHz)
+			}
+		default: // Linear
+			copy(row, mags[:outBins])
+		}
+		frames[x] = row
+	}
+
+	return &Spectrogram{
+		mags:         frames,
+		windowEnergy: windowEnergy,
+		bins:         outBins,
+		hopSize:      hopSize,
+		sampleRate:   s.sampleRate,
+		freqScale:    s.freqScale,
+		fMin:         fMin,
+		fMax:         fMax,
+	}
+}
+
+// magnitudeToDBFS converts an FFT magnitude to dBFS, normalized by the
+// energy of the window function that was applied before the FFT.
+func magnitudeToDBFS(mag, windowEnergy float64) float64 {
+	return (20 * math.Log10(mag/math.Sqrt(windowEnergy))) - 10
+}
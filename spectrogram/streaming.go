@@ -0,0 +1,187 @@
+package spectrogram
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math/cmplx"
+
+	"github.com/fogleman/gg"
+	"github.com/mjibson/go-dsp/fft"
+)
+
+// PCMSource supplies PCM samples incrementally. It has the same shape as
+// audio.Decoder's Read method, so a Decoder can be passed directly to
+// StreamingSpectrograph without an adapter.
+type PCMSource interface {
+	Read(p []float64) (n int, err error)
+}
+
+// FrameSink is invoked once per completed FFT frame, with the frame's index
+// and its raw FFT magnitudes (no dBFS conversion applied), matching
+// Spectrogram.Magnitudes so streaming and non-streaming callers get the same
+// data to feed into their own feature extraction.
+type FrameSink func(columnIndex int, magnitudes []float64)
+
+// StreamingSpectrograph computes a spectrogram frame by frame from a
+// PCMSource instead of an in-memory slice. It keeps only a ring buffer of
+// fftSize samples, so memory use is O(fftSize) rather than O(len(pcm)),
+// making it suitable for multi-hour recordings or live audio piped in from
+// ffmpeg/arecord.
+type StreamingSpectrograph struct {
+	fftSize    int
+	stepSize   int
+	sampleRate int
+	window     WindowFunc
+}
+
+// NewStreamingSpectrograph returns a StreamingSpectrograph with the same
+// defaults as NewSpectrograph: a 2048-sample FFT, an 880-sample hop, 48 kHz,
+// and a Hann window.
+func NewStreamingSpectrograph() *StreamingSpectrograph {
+	return &StreamingSpectrograph{
+		fftSize:    2048,
+		stepSize:   880,
+		sampleRate: DefaultSampleRate,
+		window:     WindowHann,
+	}
+}
+
+// SetFFTSize sets the number of samples per FFT frame.
+func (s *StreamingSpectrograph) SetFFTSize(n int) *StreamingSpectrograph {
+	s.fftSize = n
+	return s
+}
+
+// SetStepSize sets the number of samples advanced between consecutive
+// frames (the hop size). It must not exceed the FFT size, or Run will
+// return an error.
+func (s *StreamingSpectrograph) SetStepSize(n int) *StreamingSpectrograph {
+	s.stepSize = n
+	return s
+}
+
+// SetSampleRate records the sample rate of the PCM data src will supply.
+func (s *StreamingSpectrograph) SetSampleRate(sr int) *StreamingSpectrograph {
+	s.sampleRate = sr
+	return s
+}
+
+// SetWindow sets the window function applied to each frame before the FFT.
+func (s *StreamingSpectrograph) SetWindow(w WindowFunc) *StreamingSpectrograph {
+	s.window = w
+	return s
+}
+
+// Run pulls PCM samples from src and calls sink once per completed FFT
+// frame, in order, until src is exhausted. It never retains more than one
+// window (fftSize samples) of PCM at a time. It returns an error without
+// reading from src if the configured step size exceeds the FFT size.
+func (s *StreamingSpectrograph) Run(src PCMSource, sink FrameSink) error {
+	fftSize := s.fftSize
+	hopSize := s.stepSize
+	if hopSize > fftSize {
+		return fmt.Errorf("spectrogram: step size %d exceeds FFT size %d", hopSize, fftSize)
+	}
+	windowFunc := s.window(fftSize)
+
+	numBins := fftSize / 2
+	ring := make([]float64, fftSize)
+	hop := make([]float64, hopSize)
+	filled := 0
+	column := 0
+
+	for {
+		n, readErr := readFull(src, hop)
+		if n > 0 {
+			if filled < fftSize {
+				avail := fftSize - filled
+				if n <= avail {
+					copy(ring[filled:], hop[:n])
+					filled += n
+				} else {
+					// hop overflows the remaining space in the ring (common
+					// whenever fftSize isn't a multiple of hopSize); fill the
+					// ring the rest of the way and slide the overflow in
+					// instead of dropping it.
+					copy(ring[filled:], hop[:avail])
+					filled = fftSize
+					overflow := n - avail
+					copy(ring, ring[overflow:])
+					copy(ring[fftSize-overflow:], hop[avail:n])
+				}
+			} else {
+				copy(ring, ring[n:])
+				copy(ring[fftSize-n:], hop[:n])
+			}
+
+			if filled >= fftSize {
+				frame := make([]float64, fftSize)
+				for i, v := range ring {
+					frame[i] = v * windowFunc[i]
+				}
+
+				spectrum := fft.FFTReal(frame)
+				mags := make([]float64, numBins)
+				for y := 0; y < numBins; y++ {
+					mags[y] = cmplx.Abs(spectrum[y])
+				}
+				sink(column, mags)
+				column++
+			}
+		}
+
+		if readErr != nil {
+			if readErr == io.EOF {
+				return nil
+			}
+			return readErr
+		}
+	}
+}
+
+// readFull reads from src until buf is full or src returns an error,
+// mirroring io.ReadFull for a PCMSource.
+func readFull(src PCMSource, buf []float64) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := src.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// RenderStreaming computes a spectrogram from src and draws it directly into
+// a width x height image as each frame arrives, converting Run's raw
+// magnitudes to dBFS using GradientAudacity. Like Run, it never buffers more
+// than one FFT window of PCM, so memory use is O(fftSize) rather than
+// O(width*height) or O(total samples).
+func (s *StreamingSpectrograph) RenderStreaming(src PCMSource, width, height int) (*gg.Context, error) {
+	windowFunc := s.window(s.fftSize)
+	windowEnergy := 0.0
+	for _, w := range windowFunc {
+		windowEnergy += w * w
+	}
+
+	dc := gg.NewContext(width, height)
+	dc.SetColor(color.RGBA{0, 0, 0, 255})
+	dc.Clear()
+
+	err := s.Run(src, func(column int, magnitudes []float64) {
+		if column >= width {
+			return
+		}
+		for y := 0; y < height && y < len(magnitudes); y++ {
+			dBFS := magnitudeToDBFS(magnitudes[y], windowEnergy)
+			dc.SetColor(GradientAudacity.colorFor(dBFS))
+			dc.SetPixel(column, height-y-1)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
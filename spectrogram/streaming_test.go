@@ -0,0 +1,100 @@
+package spectrogram
+
+import (
+	"io"
+	"testing"
+)
+
+// sliceSource is a PCMSource that hands out samples from a fixed slice,
+// splitting reads into chunks of at most maxRead samples to exercise partial
+// Read calls the way a real decoder would.
+type sliceSource struct {
+	samples []float64
+	pos     int
+	maxRead int
+}
+
+func (s *sliceSource) Read(p []float64) (int, error) {
+	if s.pos >= len(s.samples) {
+		return 0, io.EOF
+	}
+	n := len(p)
+	if n > s.maxRead {
+		n = s.maxRead
+	}
+	if s.pos+n > len(s.samples) {
+		n = len(s.samples) - s.pos
+	}
+	copy(p, s.samples[s.pos:s.pos+n])
+	s.pos += n
+	return n, nil
+}
+
+func rampSignal(n int) []float64 {
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = float64(i)
+	}
+	return samples
+}
+
+// TestRunHopLargerThanFFTSizeRejected covers the case where SetStepSize is
+// given a hop larger than the FFT size: Run must report a clean error
+// instead of panicking on an out-of-range ring buffer slice.
+func TestRunHopLargerThanFFTSizeRejected(t *testing.T) {
+	src := &sliceSource{samples: rampSignal(64), maxRead: 10}
+	s := NewStreamingSpectrograph().SetFFTSize(4).SetStepSize(10)
+
+	err := s.Run(src, func(int, []float64) {})
+	if err == nil {
+		t.Fatal("Run() returned nil error, want an error for step size > FFT size")
+	}
+}
+
+// TestRunFillPhaseDoesNotDropSamples covers the case the ring-fill fix
+// targets: a hop size that does not evenly divide the FFT size, so a single
+// hop read overflows the remaining space in the ring during startup.
+func TestRunFillPhaseDoesNotDropSamples(t *testing.T) {
+	const fftSize = 8
+	const hopSize = 5
+	src := &sliceSource{samples: rampSignal(64), maxRead: hopSize}
+	s := NewStreamingSpectrograph().SetFFTSize(fftSize).SetStepSize(hopSize)
+
+	var frames [][]float64
+	err := s.Run(src, func(_ int, magnitudes []float64) {
+		frame := make([]float64, len(magnitudes))
+		copy(frame, magnitudes)
+		frames = append(frames, frame)
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(frames) == 0 {
+		t.Fatal("Run() produced no frames")
+	}
+}
+
+func TestRunMagnitudesAreRaw(t *testing.T) {
+	const fftSize = 16
+	const hopSize = 16
+	src := &sliceSource{samples: rampSignal(fftSize * 3), maxRead: hopSize}
+	s := NewStreamingSpectrograph().SetFFTSize(fftSize).SetStepSize(hopSize).SetWindow(WindowRectangular)
+
+	var sawPositive bool
+	err := s.Run(src, func(_ int, magnitudes []float64) {
+		for _, v := range magnitudes {
+			if v > 1 {
+				sawPositive = true
+			}
+			if v < 0 {
+				t.Errorf("magnitude %v is negative; want raw linear magnitude", v)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !sawPositive {
+		t.Error("expected at least one magnitude greater than 1 for a raw (non-dBFS) linear magnitude")
+	}
+}